@@ -0,0 +1,17 @@
+// ijump-lsp 是 ijump 解析器的常驻 LSP 服务端入口，通过 stdio 和编辑器通信
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Green-rainBit/ijump/src/lsp"
+)
+
+func main() {
+	if err := lsp.Serve(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "LSP 服务异常退出: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,65 @@
+// ijump 命令行入口：解析一个 Go 文件所在的模块/目录，
+// 输出 JSON（默认，供编辑器插件消费）或 PlantUML 类图。
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/Green-rainBit/ijump/src/parser"
+	"github.com/Green-rainBit/ijump/src/render"
+)
+
+func main() {
+	format := flag.String("format", "json", "输出格式: json 或 plantuml")
+	packageFilterExpr := flag.String("package-filter", "", "只渲染导入路径匹配该正则的包（仅对 -format plantuml 生效）")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "用法: %s [-format json|plantuml] [-package-filter regex] <go文件路径>\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	filePath := flag.Arg(0)
+	result, err := parser.AnalyzeFile(filePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "分析失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		printJSON(result)
+	case "plantuml":
+		printPlantUML(result, *packageFilterExpr)
+	default:
+		fmt.Fprintf(os.Stderr, "不支持的输出格式: %s（支持 json、plantuml）\n", *format)
+		os.Exit(1)
+	}
+}
+
+func printJSON(result parser.ParseResult) {
+	jsonResult, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "JSON编码失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(jsonResult))
+}
+
+func printPlantUML(result parser.ParseResult, packageFilterExpr string) {
+	var packageFilter *regexp.Regexp
+	if packageFilterExpr != "" {
+		compiled, err := regexp.Compile(packageFilterExpr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "package-filter 不是合法的正则表达式: %v\n", err)
+			os.Exit(1)
+		}
+		packageFilter = compiled
+	}
+
+	fmt.Println(render.PlantUML(result, packageFilter))
+}
@@ -0,0 +1,67 @@
+package lsp
+
+// 这里只定义 ijump 的 LSP 服务端实际用到的那一小部分协议类型，
+// 不追求覆盖 LSP 规范的全部字段。
+
+// Position 是从0开始计数的行/列位置
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range 是一个起止位置区间
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location 是某个文件中的一个区间
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// TextDocumentIdentifier 标识一个已打开的文档
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// TextDocumentItem 是 didOpen 携带的完整文档内容
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// TextDocumentPositionParams 是 implementation/definition 等请求的通用参数
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// InitializeParams 只取了我们需要定位工作区根目录的字段
+type InitializeParams struct {
+	RootURI  string `json:"rootUri,omitempty"`
+	RootPath string `json:"rootPath,omitempty"`
+}
+
+// DidOpenTextDocumentParams 对应 textDocument/didOpen
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+// DidChangeTextDocumentParams 对应 textDocument/didChange
+type DidChangeTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// DidSaveTextDocumentParams 对应 textDocument/didSave
+type DidSaveTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// ListInterfacesParams 是自定义请求 ijump/listInterfaces 的参数
+type ListInterfacesParams struct {
+	URI string `json:"uri"`
+}
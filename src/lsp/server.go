@@ -0,0 +1,247 @@
+// Package lsp 把 parser 包装成一个常驻的 LSP 服务端，这样编辑器扩展
+// 不再需要为每个文件单独拉起一个 ijump 进程、解析 JSON、再自己维护缓存——
+// 索引在 initialize 时建立一次，之后随 didSave 增量更新。
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/Green-rainBit/ijump/src/parser"
+)
+
+// Server 实现 jsonrpc2.Handler，持有当前的解析结果作为索引
+type Server struct {
+	mu     sync.Mutex
+	result parser.ParseResult
+}
+
+// NewServer 创建一个还没有索引内容的 Server，真正的索引在 initialize 时建立
+func NewServer() *Server {
+	return &Server{result: parser.ParseResult{Packages: make(map[string]parser.PackageInfo)}}
+}
+
+// Serve 以 stdio 为传输层启动 jsonrpc2 连接，阻塞到连接断开为止
+func Serve(ctx context.Context) error {
+	conn := jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(stdio{}, jsonrpc2.VSCodeObjectCodec{}), NewServer())
+	<-conn.DisconnectNotify()
+	return nil
+}
+
+// Handle 按 LSP 方法名分发请求/通知
+func (s *Server) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	switch req.Method {
+	case "initialize":
+		s.handleInitialize(ctx, conn, req)
+	case "textDocument/didOpen":
+		s.handleDidOpen(req)
+	case "textDocument/didChange":
+		// 索引只在打开和保存时刷新，didChange 不需要做任何事
+	case "textDocument/didSave":
+		s.handleDidSave(req)
+	case "textDocument/implementation":
+		s.handleImplementation(ctx, conn, req)
+	case "textDocument/definition":
+		s.handleDefinition(ctx, conn, req)
+	case "ijump/listInterfaces":
+		s.handleListInterfaces(ctx, conn, req)
+	default:
+		if !req.Notif {
+			_ = conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+				Code:    jsonrpc2.CodeMethodNotFound,
+				Message: fmt.Sprintf("方法未实现: %s", req.Method),
+			})
+		}
+	}
+}
+
+func (s *Server) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	var params InitializeParams
+	unmarshalParams(req, &params)
+
+	root := params.RootPath
+	if root == "" {
+		root = uriToPath(params.RootURI)
+	}
+
+	if root != "" {
+		if result, err := parser.AnalyzeFile(root); err == nil {
+			s.mu.Lock()
+			s.result = result
+			s.mu.Unlock()
+		} else {
+			fmt.Fprintf(os.Stderr, "建立初始索引失败 %s: %v\n", root, err)
+		}
+	}
+
+	_ = conn.Reply(ctx, req.ID, map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"implementationProvider": true,
+			"definitionProvider":     true,
+		},
+	})
+}
+
+func (s *Server) handleDidOpen(req *jsonrpc2.Request) {
+	var params DidOpenTextDocumentParams
+	unmarshalParams(req, &params)
+	s.reindexFile(uriToPath(params.TextDocument.URI))
+}
+
+func (s *Server) handleDidSave(req *jsonrpc2.Request) {
+	var params DidSaveTextDocumentParams
+	unmarshalParams(req, &params)
+	s.reindexFile(uriToPath(params.TextDocument.URI))
+}
+
+// reindexFile 只重新解析被打开/保存文件所在的包，而不是整份模块
+func (s *Server) reindexFile(path string) {
+	if path == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := parser.ReparseFile(&s.result, path); err != nil {
+		fmt.Fprintf(os.Stderr, "增量索引失败 %s: %v\n", path, err)
+	}
+}
+
+func (s *Server) handleImplementation(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	var params TextDocumentPositionParams
+	unmarshalParams(req, &params)
+	path := uriToPath(params.TextDocument.URI)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var locations []Location
+	for _, pkg := range s.result.Packages {
+		for _, iface := range pkg.Interfaces {
+			if iface.FilePath != path || iface.Line != params.Position.Line {
+				continue
+			}
+			for _, structName := range iface.ImplementedBy {
+				if loc, ok := findStructLocation(s.result, structName); ok {
+					locations = append(locations, loc)
+				}
+			}
+		}
+	}
+
+	_ = conn.Reply(ctx, req.ID, locations)
+}
+
+// handleDefinition 处理落在结构体声明行上的跳转请求：从被光标选中的结构体
+// 跳到它通过 "// ensure X implements Y" 一类注释声明实现的接口定义
+func (s *Server) handleDefinition(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	var params TextDocumentPositionParams
+	unmarshalParams(req, &params)
+	path := uriToPath(params.TextDocument.URI)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var locations []Location
+	for _, pkg := range s.result.Packages {
+		for _, structInfo := range pkg.Structs {
+			if structInfo.FilePath != path || structInfo.Line != params.Position.Line {
+				continue
+			}
+			for _, ifaceName := range structInfo.ImplementsInterfaces {
+				if loc, ok := findInterfaceLocation(s.result, ifaceName); ok {
+					locations = append(locations, loc)
+				}
+			}
+		}
+	}
+
+	_ = conn.Reply(ctx, req.ID, locations)
+}
+
+func (s *Server) handleListInterfaces(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	var params ListInterfacesParams
+	unmarshalParams(req, &params)
+	path := uriToPath(params.URI)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var interfaces []parser.InterfaceInfo
+	for _, pkg := range s.result.Packages {
+		for _, iface := range pkg.Interfaces {
+			if iface.FilePath == path {
+				interfaces = append(interfaces, iface)
+			}
+		}
+	}
+
+	_ = conn.Reply(ctx, req.ID, interfaces)
+}
+
+func findStructLocation(result parser.ParseResult, name string) (Location, bool) {
+	for _, pkg := range result.Packages {
+		for _, structInfo := range pkg.Structs {
+			if structInfo.Name == name {
+				return Location{URI: pathToURI(structInfo.FilePath), Range: pointRange(structInfo.Line)}, true
+			}
+		}
+	}
+	return Location{}, false
+}
+
+func findInterfaceLocation(result parser.ParseResult, name string) (Location, bool) {
+	for _, pkg := range result.Packages {
+		for _, iface := range pkg.Interfaces {
+			if iface.Name == name {
+				return Location{URI: pathToURI(iface.FilePath), Range: pointRange(iface.Line)}, true
+			}
+		}
+	}
+	return Location{}, false
+}
+
+func pointRange(line int) Range {
+	pos := Position{Line: line, Character: 0}
+	return Range{Start: pos, End: pos}
+}
+
+func unmarshalParams(req *jsonrpc2.Request, v interface{}) {
+	if req.Params == nil {
+		return
+	}
+	_ = json.Unmarshal(*req.Params, v)
+}
+
+func uriToPath(uri string) string {
+	if !strings.HasPrefix(uri, "file://") {
+		return uri
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return strings.TrimPrefix(uri, "file://")
+	}
+	return parsed.Path
+}
+
+func pathToURI(path string) string {
+	if strings.HasPrefix(path, "file://") {
+		return path
+	}
+	return "file://" + path
+}
+
+// stdio 把标准输入/输出适配成 jsonrpc2.NewBufferedStream 需要的 io.ReadWriteCloser
+type stdio struct{}
+
+func (stdio) Read(p []byte) (int, error)  { return os.Stdin.Read(p) }
+func (stdio) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdio) Close() error                { return nil }
@@ -1,10 +1,11 @@
-package main
+package parser
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/printer"
 	"go/token"
 	"os"
 	"path/filepath"
@@ -12,20 +13,44 @@ import (
 	"strings"
 )
 
+// 参数/返回值信息，类型用 go/printer 还原出的源码文本表示，
+// 这样 map[string]chan<- *pkg.Foo、泛型类型等复杂类型都能原样保留
+type Param struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Variadic bool   `json:"variadic"`
+}
+
 // 接口方法信息
 type MethodInfo struct {
-	Name     string `json:"name"`
-	Line     int    `json:"line"`
-	FilePath string `json:"filePath"`
+	Name     string  `json:"name"`
+	Line     int     `json:"line"`
+	FilePath string  `json:"filePath"`
+	Params   []Param `json:"params,omitempty"`
+	Results  []Param `json:"results,omitempty"`
+}
+
+// Signature 返回只由参数/返回值类型组成的规范化签名字符串（忽略参数名），
+// 用于在 go/types 不可用时做低成本的签名相等性比较
+func (m MethodInfo) Signature() string {
+	return signatureString(m.Params, m.Results)
+}
+
+// 类型形参信息，用于泛型接口/结构体/方法
+type TypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
 }
 
 // 接口定义信息
 type InterfaceInfo struct {
-	Name         string       `json:"name"`
-	Line         int          `json:"line"`
-	FilePath     string       `json:"filePath"`
-	Methods      []MethodInfo `json:"methods"`
-	InternalType string       `json:"internalType,omitempty"` // 可能的内嵌接口名
+	Name          string       `json:"name"`
+	Line          int          `json:"line"`
+	FilePath      string       `json:"filePath"`
+	Methods       []MethodInfo `json:"methods"`
+	InternalType  string       `json:"internalType,omitempty"`  // 可能的内嵌接口名
+	ImplementedBy []string     `json:"implementedBy,omitempty"` // 通过 go/types 计算出的实现该接口的结构体
+	TypeParams    []TypeParam  `json:"typeParams,omitempty"`    // 泛型接口的类型形参列表
 }
 
 // 结构体字段信息
@@ -45,15 +70,51 @@ type StructInfo struct {
 	FilePath             string      `json:"filePath"`
 	Fields               []FieldInfo `json:"fields"`
 	ImplementsInterfaces []string    `json:"implementsInterfaces,omitempty"` // 通过注释声明实现的接口
+	Implements           []string    `json:"implements,omitempty"`           // 通过 go/types 计算出的真实实现关系；go/types 没有结果的包里是经方法签名比对确认过的注释声明
+	TypeParams           []TypeParam `json:"typeParams,omitempty"`           // 泛型结构体的类型形参列表
 }
 
 // 方法实现信息
 type ImplementationInfo struct {
-	ReceiverType string `json:"receiverType"`
-	MethodName   string `json:"methodName"`
-	Line         int    `json:"line"`
-	FilePath     string `json:"filePath"`
-	IsPointer    bool   `json:"isPointer"`
+	ReceiverType string      `json:"receiverType"`
+	MethodName   string      `json:"methodName"`
+	Line         int         `json:"line"`
+	FilePath     string      `json:"filePath"`
+	IsPointer    bool        `json:"isPointer"`
+	TypeParams   []TypeParam `json:"typeParams,omitempty"` // 方法自身的类型形参（区别于接收者的类型实参）
+	Params       []Param     `json:"params,omitempty"`
+	Results      []Param     `json:"results,omitempty"`
+}
+
+// Signature 返回只由参数/返回值类型组成的规范化签名字符串（忽略参数名），
+// 用于在 go/types 不可用时做低成本的签名相等性比较
+func (i ImplementationInfo) Signature() string {
+	return signatureString(i.Params, i.Results)
+}
+
+// signatureString 把参数/返回值列表拼成 "(T1, T2) (R1, R2)" 形式的规范签名
+func signatureString(params, results []Param) string {
+	paramTypes := make([]string, len(params))
+	for i, p := range params {
+		paramTypes[i] = p.Type
+	}
+
+	sig := "(" + strings.Join(paramTypes, ", ") + ")"
+
+	switch len(results) {
+	case 0:
+		// 无返回值
+	case 1:
+		sig += " " + results[0].Type
+	default:
+		resultTypes := make([]string, len(results))
+		for i, r := range results {
+			resultTypes[i] = r.Type
+		}
+		sig += " (" + strings.Join(resultTypes, ", ") + ")"
+	}
+
+	return sig
 }
 
 // 包信息
@@ -70,9 +131,9 @@ type ParseResult struct {
 	Packages map[string]PackageInfo `json:"packages"`
 }
 
-// 从文件位置获取行号
+// 从文件位置获取行号，直接返回 LSP/编辑器约定的从0开始计数的行号
+// （go/token 的 Position.Line 是从1开始的）
 func getLineFromPos(fset *token.FileSet, pos token.Pos) int {
-	// 返回行号减1，使装饰显示在方法定义行
 	return fset.Position(pos).Line - 1
 }
 
@@ -82,17 +143,120 @@ func getTypeNameFromExpr(expr ast.Expr) (name string, isPointer bool) {
 	case *ast.Ident:
 		return t.Name, false
 	case *ast.StarExpr:
-		if ident, ok := t.X.(*ast.Ident); ok {
-			return ident.Name, true
+		innerName, _ := getTypeNameFromExpr(t.X)
+		if innerName == "" {
+			return "", false
 		}
+		return innerName, true
 	case *ast.SelectorExpr:
 		if ident, ok := t.X.(*ast.Ident); ok {
 			return ident.Name + "." + t.Sel.Name, false
 		}
+	case *ast.IndexExpr:
+		// 单个类型实参的泛型实例化，例如 Store[K]
+		return genericTypeName(t.X, []ast.Expr{t.Index})
+	case *ast.IndexListExpr:
+		// 多个类型实参的泛型实例化（Go 1.18+），例如 Store[K, V]
+		return genericTypeName(t.X, t.Indices)
 	}
 	return "", false
 }
 
+// genericTypeName 把泛型实例化表达式的基础类型和类型实参拼成
+// "Base[Arg1, Arg2]" 这样的类型名，保留指针/实参信息不完整时退化为基础类型名
+func genericTypeName(base ast.Expr, typeArgs []ast.Expr) (name string, isPointer bool) {
+	baseName, isPointer := getTypeNameFromExpr(base)
+	if baseName == "" {
+		return "", false
+	}
+
+	argNames := make([]string, 0, len(typeArgs))
+	for _, arg := range typeArgs {
+		argName, _ := getTypeNameFromExpr(arg)
+		if argName == "" {
+			return baseName, isPointer
+		}
+		argNames = append(argNames, argName)
+	}
+	if len(argNames) == 0 {
+		return baseName, isPointer
+	}
+	return baseName + "[" + strings.Join(argNames, ", ") + "]", isPointer
+}
+
+// exprToSource 用 go/printer 把类型表达式还原成源码文本，
+// 这样 map[string]chan<- *pkg.Foo、func(int) error、泛型类型等复杂类型
+// 都能原样保留，而不是像 getTypeNameFromExpr 那样只认识几种简单形态
+func exprToSource(fset *token.FileSet, expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// paramsFromFieldList 把一个参数/返回值字段列表展开成 []Param，
+// 每个字段可能同时声明多个同类型的形参（如 "a, b int"），也可能是变长参数
+func paramsFromFieldList(fset *token.FileSet, fields *ast.FieldList) []Param {
+	if fields == nil {
+		return nil
+	}
+
+	var params []Param
+	for _, field := range fields.List {
+		_, variadic := field.Type.(*ast.Ellipsis)
+		typeName := exprToSource(fset, field.Type)
+
+		if len(field.Names) == 0 {
+			params = append(params, Param{Type: typeName, Variadic: variadic})
+			continue
+		}
+		for _, name := range field.Names {
+			params = append(params, Param{Name: name.Name, Type: typeName, Variadic: variadic})
+		}
+	}
+	return params
+}
+
+// extractTypeParams 从类型形参列表（TypeSpec.TypeParams 或
+// FuncDecl.Type.TypeParams）里提取形参名和约束，供泛型接口/结构体/方法使用
+func extractTypeParams(fieldList *ast.FieldList) []TypeParam {
+	if fieldList == nil {
+		return nil
+	}
+
+	var params []TypeParam
+	for _, field := range fieldList.List {
+		constraint := constraintString(field.Type)
+		for _, name := range field.Names {
+			params = append(params, TypeParam{Name: name.Name, Constraint: constraint})
+		}
+	}
+	return params
+}
+
+// constraintString 把类型形参的约束表达式渲染成可读字符串，
+// 支持常见写法：普通类型、~底层类型、A | B 联合；interface{...} 字面量
+// 不展开其内容，一律渲染为 any
+func constraintString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.UnaryExpr:
+		return "~" + constraintString(t.X)
+	case *ast.BinaryExpr:
+		return constraintString(t.X) + " | " + constraintString(t.Y)
+	case *ast.InterfaceType:
+		return "any"
+	default:
+		if name, _ := getTypeNameFromExpr(expr); name != "" {
+			return name
+		}
+		return "any"
+	}
+}
+
 // 从注释中解析 "ensure X implements Y" 格式的接口声明
 // 支持格式:
 //   - // ensure StructName implements InterfaceName
@@ -173,6 +337,146 @@ func extractImplementsFromComments(commentGroup *ast.CommentGroup, structName st
 	return interfaces
 }
 
+// parseGoFileAST 遍历单个已解析文件的AST，把接口、结构体和方法实现
+// 追加到 pkgInfo 中。parseDirectory 和 parseModule 共用这份逻辑，
+// 区别只在于它们如何划分目录、如何共享 fset。
+func parseGoFileAST(fset *token.FileSet, path string, node *ast.File, pkgInfo *PackageInfo) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch decl := n.(type) {
+		case *ast.GenDecl:
+			if decl.Tok == token.TYPE {
+				for _, spec := range decl.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						// 解析接口
+						if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+							interfaceInfo := InterfaceInfo{
+								Name:       typeSpec.Name.Name,
+								Line:       getLineFromPos(fset, typeSpec.Pos()),
+								FilePath:   path,
+								Methods:    []MethodInfo{},
+								TypeParams: extractTypeParams(typeSpec.TypeParams),
+							}
+
+							// 解析接口方法
+							for _, field := range interfaceType.Methods.List {
+								if len(field.Names) > 0 {
+									// 命名方法
+									var params, results []Param
+									if funcType, ok := field.Type.(*ast.FuncType); ok {
+										params = paramsFromFieldList(fset, funcType.Params)
+										results = paramsFromFieldList(fset, funcType.Results)
+									}
+									for _, name := range field.Names {
+										methodInfo := MethodInfo{
+											Name:     name.Name,
+											Line:     getLineFromPos(fset, field.Pos()),
+											FilePath: path,
+											Params:   params,
+											Results:  results,
+										}
+										interfaceInfo.Methods = append(interfaceInfo.Methods, methodInfo)
+									}
+								} else {
+									// 嵌入接口
+									typeName, _ := getTypeNameFromExpr(field.Type)
+									if typeName != "" {
+										interfaceInfo.InternalType = typeName
+									}
+								}
+							}
+
+							pkgInfo.Interfaces = append(pkgInfo.Interfaces, interfaceInfo)
+						}
+
+						// 解析结构体
+						if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+							structInfo := StructInfo{
+								Name:       typeSpec.Name.Name,
+								Line:       getLineFromPos(fset, typeSpec.Pos()),
+								FilePath:   path,
+								Fields:     []FieldInfo{},
+								TypeParams: extractTypeParams(typeSpec.TypeParams),
+							}
+
+							// 从注释中提取接口实现声明
+							// 优先检查 typeSpec 的注释，然后检查 decl 的注释
+							var implementsInterfaces []string
+							if typeSpec.Doc != nil {
+								implementsInterfaces = extractImplementsFromComments(typeSpec.Doc, typeSpec.Name.Name)
+							}
+							if len(implementsInterfaces) == 0 && decl.Doc != nil {
+								implementsInterfaces = extractImplementsFromComments(decl.Doc, typeSpec.Name.Name)
+							}
+							if len(implementsInterfaces) > 0 {
+								structInfo.ImplementsInterfaces = implementsInterfaces
+							}
+
+							// 解析结构体字段
+							// Type 用 go/printer 还原出完整的源码文本，这样 map[K]V、
+							// chan T、func(...) 这些 getTypeNameFromExpr 认不出的复杂
+							// 类型也能正确渲染，而不是留空
+							for _, field := range structType.Fields.List {
+								embedName, isPointer := getTypeNameFromExpr(field.Type)
+								typeName := exprToSource(fset, field.Type)
+
+								if len(field.Names) == 0 {
+									// 嵌入字段：字段名和内嵌标识符保持一致，不用完整的类型表达式文本
+									fieldInfo := FieldInfo{
+										Name:      embedName,
+										Type:      typeName,
+										Line:      getLineFromPos(fset, field.Pos()),
+										FilePath:  path,
+										Embedded:  true,
+										IsPointer: isPointer,
+									}
+									structInfo.Fields = append(structInfo.Fields, fieldInfo)
+								} else {
+									// 命名字段
+									for _, name := range field.Names {
+										fieldInfo := FieldInfo{
+											Name:      name.Name,
+											Type:      typeName,
+											Line:      getLineFromPos(fset, field.Pos()),
+											FilePath:  path,
+											Embedded:  false,
+											IsPointer: isPointer,
+										}
+										structInfo.Fields = append(structInfo.Fields, fieldInfo)
+									}
+								}
+							}
+
+							pkgInfo.Structs = append(pkgInfo.Structs, structInfo)
+						}
+					}
+				}
+			}
+
+		case *ast.FuncDecl:
+			// 解析方法实现
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				recvField := decl.Recv.List[0]
+				typeName, isPointer := getTypeNameFromExpr(recvField.Type)
+
+				if typeName != "" {
+					methodInfo := ImplementationInfo{
+						ReceiverType: typeName,
+						MethodName:   decl.Name.Name,
+						Line:         getLineFromPos(fset, decl.Pos()),
+						FilePath:     path,
+						IsPointer:    isPointer,
+						TypeParams:   extractTypeParams(decl.Type.TypeParams),
+						Params:       paramsFromFieldList(fset, decl.Type.Params),
+						Results:      paramsFromFieldList(fset, decl.Type.Results),
+					}
+					pkgInfo.Methods = append(pkgInfo.Methods, methodInfo)
+				}
+			}
+		}
+		return true
+	})
+}
+
 // 递归解析目录下的Go文件
 func parseDirectory(dirPath string) (ParseResult, error) {
 	result := ParseResult{
@@ -222,125 +526,8 @@ func parseDirectory(dirPath string) (ParseResult, error) {
 
 			pkgInfo := result.Packages[packagePath]
 
-			// 解析接口和结构体
-			ast.Inspect(node, func(n ast.Node) bool {
-				switch decl := n.(type) {
-				case *ast.GenDecl:
-					if decl.Tok == token.TYPE {
-						for _, spec := range decl.Specs {
-							if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-								// 解析接口
-								if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
-									interfaceInfo := InterfaceInfo{
-										Name:     typeSpec.Name.Name,
-										Line:     getLineFromPos(fset, typeSpec.Pos()),
-										FilePath: path,
-										Methods:  []MethodInfo{},
-									}
-
-									// 解析接口方法
-									for _, field := range interfaceType.Methods.List {
-										if len(field.Names) > 0 {
-											// 命名方法
-											for _, name := range field.Names {
-												methodInfo := MethodInfo{
-													Name:     name.Name,
-													Line:     getLineFromPos(fset, field.Pos()),
-													FilePath: path,
-												}
-												interfaceInfo.Methods = append(interfaceInfo.Methods, methodInfo)
-											}
-										} else {
-											// 嵌入接口
-											typeName, _ := getTypeNameFromExpr(field.Type)
-											if typeName != "" {
-												interfaceInfo.InternalType = typeName
-											}
-										}
-									}
-
-									pkgInfo.Interfaces = append(pkgInfo.Interfaces, interfaceInfo)
-								}
-
-								// 解析结构体
-								if structType, ok := typeSpec.Type.(*ast.StructType); ok {
-									structInfo := StructInfo{
-										Name:     typeSpec.Name.Name,
-										Line:     getLineFromPos(fset, typeSpec.Pos()),
-										FilePath: path,
-										Fields:   []FieldInfo{},
-									}
-
-									// 从注释中提取接口实现声明
-									// 优先检查 typeSpec 的注释，然后检查 decl 的注释
-									var implementsInterfaces []string
-									if typeSpec.Doc != nil {
-										implementsInterfaces = extractImplementsFromComments(typeSpec.Doc, typeSpec.Name.Name)
-									}
-									if len(implementsInterfaces) == 0 && decl.Doc != nil {
-										implementsInterfaces = extractImplementsFromComments(decl.Doc, typeSpec.Name.Name)
-									}
-									if len(implementsInterfaces) > 0 {
-										structInfo.ImplementsInterfaces = implementsInterfaces
-									}
-
-									// 解析结构体字段
-									for _, field := range structType.Fields.List {
-										typeName, isPointer := getTypeNameFromExpr(field.Type)
-
-										if len(field.Names) == 0 {
-											// 嵌入字段
-											fieldInfo := FieldInfo{
-												Name:      typeName, // 嵌入字段名与类型相同
-												Type:      typeName,
-												Line:      getLineFromPos(fset, field.Pos()),
-												FilePath:  path,
-												Embedded:  true,
-												IsPointer: isPointer,
-											}
-											structInfo.Fields = append(structInfo.Fields, fieldInfo)
-										} else {
-											// 命名字段
-											for _, name := range field.Names {
-												fieldInfo := FieldInfo{
-													Name:      name.Name,
-													Type:      typeName,
-													Line:      getLineFromPos(fset, field.Pos()),
-													FilePath:  path,
-													Embedded:  false,
-													IsPointer: isPointer,
-												}
-												structInfo.Fields = append(structInfo.Fields, fieldInfo)
-											}
-										}
-									}
-
-									pkgInfo.Structs = append(pkgInfo.Structs, structInfo)
-								}
-							}
-						}
-					}
-
-				case *ast.FuncDecl:
-					// 解析方法实现
-					if decl.Recv != nil && len(decl.Recv.List) > 0 {
-						recvField := decl.Recv.List[0]
-						typeName, isPointer := getTypeNameFromExpr(recvField.Type)
-
-						if typeName != "" {
-							methodInfo := ImplementationInfo{
-								ReceiverType: typeName,
-								MethodName:   decl.Name.Name,
-								Line:         getLineFromPos(fset, decl.Pos()),
-								FilePath:     path,
-								IsPointer:    isPointer,
-							}
-							pkgInfo.Methods = append(pkgInfo.Methods, methodInfo)
-						}
-					}
-				}
-				return true
-			})
+			// 解析接口、结构体和方法实现
+			parseGoFileAST(fset, path, node, &pkgInfo)
 
 			// 更新包信息
 			result.Packages[packagePath] = pkgInfo
@@ -363,35 +550,21 @@ func parseDirectory(dirPath string) (ParseResult, error) {
 		}
 	}
 
-	return result, nil
-}
+	// 尝试用 go/types 计算真实的接口实现关系，覆盖/补充注释解析的结果；
+	// 类型检查失败的包会被跳过，继续使用上面基于注释的兜底结果
+	resolveImplementsWithTypes(dirPath, &result)
 
-// 分析指定文件和相关包
-func analyzeFile(filePath string) (ParseResult, error) {
-	// 获取文件所在目录
-	dirPath := filepath.Dir(filePath)
-	return parseDirectory(dirPath)
+	return result, nil
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "用法: %s <go文件路径>\n", os.Args[0])
-		os.Exit(1)
-	}
-
-	filePath := os.Args[1]
-	result, err := analyzeFile(filePath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "分析失败: %v\n", err)
-		os.Exit(1)
+// AnalyzeFile 分析指定文件和相关包，是本包对外暴露的主入口
+func AnalyzeFile(filePath string) (ParseResult, error) {
+	// 优先按模块解析：定位 go.mod、扫描整个模块树、得到统一的 ParseResult
+	if result, err := parseModule(filePath); err == nil {
+		return result, nil
 	}
 
-	// 输出JSON结果
-	jsonResult, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "JSON编码失败: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println(string(jsonResult))
+	// 找不到 go.mod（或模块解析失败）时，退回到原来的单目录解析
+	dirPath := filepath.Dir(filePath)
+	return parseDirectory(dirPath)
 }
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// 模块解析时总是跳过的目录名，和 .gitignore 里的规则叠加生效
+var alwaysSkippedDirs = map[string]bool{
+	"vendor":   true,
+	".git":     true,
+	".idea":    true,
+	"testdata": true,
+}
+
+// parseModule 定位 entryPath 所属的模块根目录，解析其 go.mod 得到模块路径，
+// 然后遍历整个模块树解析每一个包，产出一份以模块相对导入路径为键的
+// 统一 ParseResult。所有文件共用同一个 token.FileSet，这样跨文件/跨包的
+// 行号信息保持一致。
+//
+// 如果从 entryPath 开始向上找不到 go.mod，返回错误，调用方可以退回到
+// 单目录的 parseDirectory。
+func parseModule(entryPath string) (ParseResult, error) {
+	result := ParseResult{Packages: make(map[string]PackageInfo)}
+
+	startDir := entryPath
+	if info, err := os.Stat(entryPath); err == nil && !info.IsDir() {
+		startDir = filepath.Dir(entryPath)
+	}
+
+	root, modulePath, err := findModuleRoot(startDir)
+	if err != nil {
+		return result, err
+	}
+
+	ignorePatterns := loadGitignorePatterns(root)
+
+	// 先按目录收集所有 .go 文件，再统一解析，这样每个目录只对应一个包
+	filesByDir := make(map[string][]string)
+	walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 单个目录读取失败不应中断整次遍历
+			fmt.Fprintf(os.Stderr, "遍历路径失败 %s: %v\n", path, err)
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			rel = path
+		}
+
+		if d.IsDir() {
+			if path != root && (alwaysSkippedDirs[d.Name()] || matchesIgnorePattern(rel, ignorePatterns)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") || matchesIgnorePattern(rel, ignorePatterns) {
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		filesByDir[dir] = append(filesByDir[dir], path)
+		return nil
+	})
+	if walkErr != nil {
+		return result, walkErr
+	}
+
+	fset := token.NewFileSet()
+	for dir, goFiles := range filesByDir {
+		var pkgInfo PackageInfo
+		importPath := moduleImportPath(root, modulePath, dir)
+
+		for _, path := range goFiles {
+			node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "解析文件失败 %s: %v\n", path, err)
+				continue
+			}
+
+			if pkgInfo.Name == "" {
+				pkgInfo = PackageInfo{
+					Path:       importPath,
+					Name:       node.Name.Name,
+					Interfaces: []InterfaceInfo{},
+					Structs:    []StructInfo{},
+					Methods:    []ImplementationInfo{},
+				}
+			}
+
+			parseGoFileAST(fset, path, node, &pkgInfo)
+		}
+
+		if pkgInfo.Name != "" {
+			result.Packages[importPath] = pkgInfo
+		}
+	}
+
+	resolveImplementsWithTypes(root, &result)
+
+	return result, nil
+}
+
+// findModuleRoot 从 dir 开始向上查找 go.mod，返回模块根目录和 go.mod 中
+// 声明的模块路径。
+func findModuleRoot(dir string) (root string, modulePath string, err error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		goModPath := filepath.Join(current, "go.mod")
+		if data, readErr := os.ReadFile(goModPath); readErr == nil {
+			modFile, parseErr := modfile.Parse(goModPath, data, nil)
+			if parseErr != nil {
+				return "", "", fmt.Errorf("解析 go.mod 失败 %s: %w", goModPath, parseErr)
+			}
+			if modFile.Module == nil {
+				return "", "", fmt.Errorf("go.mod 缺少 module 声明: %s", goModPath)
+			}
+			return current, modFile.Module.Mod.Path, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", "", fmt.Errorf("未找到 go.mod，起始目录: %s", dir)
+		}
+		current = parent
+	}
+}
+
+// loadGitignorePatterns 读取模块根目录下 .gitignore 中的规则，
+// 忽略空行和注释行。不支持 .gitignore 的全部语法，只做简单匹配。
+func loadGitignorePatterns(root string) []string {
+	data, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+// matchesIgnorePattern 检查模块相对路径 relPath 是否命中 patterns 中的某一条。
+// 这是 .gitignore 规则的简化实现：按路径的最后一段和完整相对路径做
+// filepath.Match，对以 "/" 结尾的目录规则去掉尾部斜杠再匹配。
+func matchesIgnorePattern(relPath string, patterns []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	base := filepath.Base(relPath)
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(pattern, "/")
+		pattern = strings.TrimSuffix(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+
+		if matched, _ := filepath.Match(pattern, base); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, relPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleImportPath 把文件系统目录转换成模块相对的导入路径，
+// 例如 root=/repo, modulePath=example.com/foo, dir=/repo/src/parser
+// 得到 example.com/foo/src/parser。
+func moduleImportPath(root, modulePath, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return modulePath
+	}
+	return modulePath + "/" + filepath.ToSlash(rel)
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+)
+
+// ReparseFile 只重新解析 filePath 所在目录（即它所在的包），并把结果写回
+// result.Packages，而不是把整个模块重新扫一遍。用于 LSP 服务端的
+// textDocument/didSave：保存一个文件只需要刷新它所属包的那一份 PackageInfo。
+//
+// result 应该是之前由 AnalyzeFile 产出的、已经按模块导入路径键入的结果；
+// 如果连 go.mod 都定位不到，就返回错误，调用方可以退回到全量重新索引。
+func ReparseFile(result *ParseResult, filePath string) error {
+	dir := filepath.Dir(filePath)
+
+	root, modulePath, err := findModuleRoot(dir)
+	if err != nil {
+		return err
+	}
+
+	goFiles, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return fmt.Errorf("查找Go文件失败 %s: %w", dir, err)
+	}
+
+	importPath := moduleImportPath(root, modulePath, dir)
+
+	fset := token.NewFileSet()
+	var pkgInfo PackageInfo
+	for _, path := range goFiles {
+		node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "解析文件失败 %s: %v\n", path, err)
+			continue
+		}
+
+		if pkgInfo.Name == "" {
+			pkgInfo = PackageInfo{
+				Path:       importPath,
+				Name:       node.Name.Name,
+				Interfaces: []InterfaceInfo{},
+				Structs:    []StructInfo{},
+				Methods:    []ImplementationInfo{},
+			}
+		}
+
+		parseGoFileAST(fset, path, node, &pkgInfo)
+	}
+
+	if pkgInfo.Name == "" {
+		delete(result.Packages, importPath)
+		return nil
+	}
+
+	result.Packages[importPath] = pkgInfo
+
+	// 增量重建该包的真实实现关系；跨包的实现关系（该包的结构体实现了
+	// 另一个包声明的接口）需要一次全量索引才能覆盖，这里先保证本包内的正确
+	resolveImplementsWithTypes(dir, result)
+
+	return nil
+}
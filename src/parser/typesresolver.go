@@ -0,0 +1,317 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// resolveImplementsWithTypes 使用 go/types 加载 dirPath 下的包，计算结构体与
+// 接口之间真实的实现关系（包括通过内嵌字段提升的方法以及指针/值接收者的
+// 差异），并把结果合并进 result 中已有的 StructInfo/InterfaceInfo。
+//
+// 如果某个包加载或类型检查失败，就跳过该包，调用方继续依赖 parseDirectory
+// 已经算出的基于注释的 ImplementsInterfaces 作为兜底。
+func resolveImplementsWithTypes(dirPath string, result *ParseResult) {
+	cfg := &packages.Config{
+		Mode: packages.LoadSyntax | packages.LoadTypes,
+		Dir:  dirPath,
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go/types 加载包失败，回退到基于方法签名的注释验证: %v\n", err)
+		applySignatureFallback(result, nil)
+		return
+	}
+
+	// parseDirectory 以文件系统目录为键，parseModule 以模块导入路径为键，
+	// 两种调用方式都要能找到对应的 PackageInfo
+	loaded := make(map[string]*packages.Package)
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 || pkg.Types == nil {
+			// 类型检查失败，保留该包已有的注释兜底结果，稍后用签名比对验证
+			continue
+		}
+		if key := packageKeyOf(result, pkg); key != "" {
+			loaded[key] = pkg
+		}
+	}
+
+	// 先从所有加载成功的包里收集接口，这样结构体可以匹配整个模块范围内的
+	// 接口，而不只是自己包内声明的那些——跨包的实现关系（比如 impl 包里的
+	// 结构体实现 api 包里声明的接口）正是 go/types 匹配相比注释解析的意义所在
+	var ifaces []namedIface
+	for key, pkg := range loaded {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			// 泛型接口的类型形参要先实例化成具体类型，否则下面的
+			// types.Implements 对着未实例化的类型形参永远返回 false
+			if iface, ok := instantiateForMatch(named).Underlying().(*types.Interface); ok && iface.NumMethods() > 0 {
+				ifaces = append(ifaces, namedIface{name: name, typ: iface, pkgKey: key})
+			}
+		}
+	}
+
+	for key, pkg := range loaded {
+		pkgInfo := result.Packages[key]
+		applyImplementsForPackage(pkg.Types.Scope(), &pkgInfo, ifaces, result)
+		result.Packages[key] = pkgInfo
+	}
+
+	// 对 go/types 没能确认实现关系的结构体（加载失败的包、或类型检查失败的包），
+	// 用方法签名比对验证一遍注释声明，而不是盲目信任注释。loaded 里的包已经
+	// 有 go/types 给出的权威结果，不应该被签名比对这种更弱的启发式覆盖
+	applySignatureFallback(result, loaded)
+}
+
+// applySignatureFallback 对每个还没有 go/types 实现关系的结构体，比较它的方法
+// 签名和注释声明的接口方法签名，确认后才写回 Implements。resolved 是已经
+// 被 go/types 成功类型检查过的包（resolveImplementsWithTypes 里的 loaded），
+// 这些包直接跳过——go/types 给出的"没有实现"同样是权威结果，不能让签名比对
+// 这种更弱的启发式基于一条未经验证的注释把它覆盖掉。
+func applySignatureFallback(result *ParseResult, resolved map[string]*packages.Package) {
+	for path, pkgInfo := range result.Packages {
+		if _, ok := resolved[path]; ok {
+			continue
+		}
+		verifyImplementsBySignature(&pkgInfo, result.Packages)
+		result.Packages[path] = pkgInfo
+	}
+}
+
+func verifyImplementsBySignature(pkgInfo *PackageInfo, allPackages map[string]PackageInfo) {
+	// 泛型方法的 ReceiverType 带着类型实参后缀（如 "Store[K]"），而
+	// StructInfo.Name 是不带类型实参的 "Store"，按去掉后缀的基础名建索引
+	// 两边才能对上
+	methodsByReceiver := make(map[string][]ImplementationInfo)
+	for _, m := range pkgInfo.Methods {
+		base := stripTypeArgs(m.ReceiverType)
+		methodsByReceiver[base] = append(methodsByReceiver[base], m)
+	}
+
+	for i := range pkgInfo.Structs {
+		structInfo := &pkgInfo.Structs[i]
+		if len(structInfo.Implements) > 0 {
+			continue // go/types 已经给出了准确结果，不需要再猜
+		}
+
+		for _, ifaceName := range structInfo.ImplementsInterfaces {
+			iface, ok := findInterfaceByName(allPackages, ifaceName)
+			if !ok {
+				continue
+			}
+			if methodsMatchInterface(methodsByReceiver[structInfo.Name], iface, allPackages) {
+				structInfo.Implements = appendUnique(structInfo.Implements, ifaceName)
+			}
+		}
+	}
+}
+
+// stripTypeArgs 去掉类型实参后缀，例如 "Store[K]" -> "Store"，
+// 这样泛型方法的接收者类型能和 StructInfo.Name 对上
+func stripTypeArgs(typeName string) string {
+	if idx := strings.Index(typeName, "["); idx >= 0 {
+		return typeName[:idx]
+	}
+	return typeName
+}
+
+// findInterfaceByName 在所有已解析的包里按名字查找接口定义
+func findInterfaceByName(allPackages map[string]PackageInfo, name string) (InterfaceInfo, bool) {
+	for _, pkg := range allPackages {
+		for _, iface := range pkg.Interfaces {
+			if iface.Name == name {
+				return iface, true
+			}
+		}
+	}
+	return InterfaceInfo{}, false
+}
+
+// methodsMatchInterface 检查 methods 里是否每一个接口方法都有签名完全一致的实现，
+// 包括通过内嵌接口（InterfaceInfo.InternalType）提升的方法
+func methodsMatchInterface(methods []ImplementationInfo, iface InterfaceInfo, allPackages map[string]PackageInfo) bool {
+	required, ok := collectInterfaceMethods(iface, allPackages, make(map[string]bool))
+	if !ok {
+		// 内嵌接口没能在已解析的包里找到定义，方法集不完整，
+		// 宁可不确认也不要给出误报
+		return false
+	}
+
+	for _, ifaceMethod := range required {
+		found := false
+		for _, m := range methods {
+			if m.MethodName == ifaceMethod.Name && m.Signature() == ifaceMethod.Signature() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// collectInterfaceMethods 展开 iface 自身声明的方法，以及（递归地）
+// InternalType 指向的内嵌接口提升的方法。ok 为 false 表示某一层内嵌接口
+// 没能在已解析的包里找到定义，调用方不应该据此确认实现关系。
+func collectInterfaceMethods(iface InterfaceInfo, allPackages map[string]PackageInfo, visited map[string]bool) ([]MethodInfo, bool) {
+	if visited[iface.Name] {
+		return iface.Methods, true
+	}
+	visited[iface.Name] = true
+
+	methods := append([]MethodInfo{}, iface.Methods...)
+
+	if iface.InternalType == "" {
+		return methods, true
+	}
+
+	embedded, ok := findInterfaceByName(allPackages, iface.InternalType)
+	if !ok {
+		return methods, false
+	}
+
+	embeddedMethods, ok := collectInterfaceMethods(embedded, allPackages, visited)
+	if !ok {
+		return methods, false
+	}
+	return append(methods, embeddedMethods...), true
+}
+
+// namedIface 是一个接口连同它所属包的 PackageInfo 键，跨包匹配时
+// 需要知道匹配到的接口应该把 ImplementedBy 写回哪个包
+type namedIface struct {
+	name   string
+	typ    *types.Interface
+	pkgKey string
+}
+
+// instantiateForMatch 把 named 的类型形参统一代入 any，得到一个可以直接
+// 传给 types.Implements 的具体类型。没有类型形参的普通命名类型原样返回。
+//
+// 结构体和接口各自声明的类型形参是两个不同的 *types.TypeParam 对象，即使
+// 名字都叫 K，types.Implements 也认不出它们是"同一个未知类型"，对未实例化
+// 的泛型类型永远返回 false。这里把两边都换成同一个具体类型 any 再比较，
+// 只要方法签名里确实统一引用了各自的类型形参，比较结果就能正确反映泛型
+// 层面的实现关系。validate 传 false，跳过类型实参是否满足约束的检查——
+// 我们只是用 any 占位做结构匹配，不关心真实调用点会代入什么类型。
+func instantiateForMatch(named *types.Named) *types.Named {
+	tparams := named.TypeParams()
+	if tparams == nil || tparams.Len() == 0 {
+		return named
+	}
+
+	anyType := types.Universe.Lookup("any").Type()
+	targs := make([]types.Type, tparams.Len())
+	for i := range targs {
+		targs[i] = anyType
+	}
+
+	instantiated, err := types.Instantiate(nil, named, targs, false)
+	if err != nil {
+		return named
+	}
+	result, ok := instantiated.(*types.Named)
+	if !ok {
+		return named
+	}
+	return result
+}
+
+// applyImplementsForPackage 在 pkgInfo 所属包的类型作用域中查找每一个结构体，
+// 拿它去匹配 ifaces 里收集到的、整个模块范围内的接口（可能来自其他包），
+// 把真实的实现关系写回 pkgInfo.Structs[i].Implements 和对应接口所属包的
+// Interfaces[j].ImplementedBy。
+func applyImplementsForPackage(scope *types.Scope, pkgInfo *PackageInfo, ifaces []namedIface, result *ParseResult) {
+	for i := range pkgInfo.Structs {
+		structInfo := &pkgInfo.Structs[i]
+
+		tn, ok := scope.Lookup(structInfo.Name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		named, ok := tn.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		if _, ok := named.Underlying().(*types.Struct); !ok {
+			continue
+		}
+
+		// 泛型结构体同样要先实例化，这样 Store[K] 才能和上面同样实例化过的
+		// Getter[K] 比出真实的方法集匹配关系
+		instNamed := instantiateForMatch(named)
+		ptr := types.NewPointer(instNamed)
+
+		for _, ni := range ifaces {
+			if !types.Implements(instNamed, ni.typ) && !types.Implements(ptr, ni.typ) {
+				continue
+			}
+
+			structInfo.Implements = appendUnique(structInfo.Implements, ni.name)
+
+			ifacePkg := result.Packages[ni.pkgKey]
+			for j := range ifacePkg.Interfaces {
+				if ifacePkg.Interfaces[j].Name == ni.name {
+					ifacePkg.Interfaces[j].ImplementedBy = appendUnique(ifacePkg.Interfaces[j].ImplementedBy, structInfo.Name)
+				}
+			}
+			result.Packages[ni.pkgKey] = ifacePkg
+		}
+	}
+}
+
+// packageDirOf 返回包编译文件所在的目录，用于匹配 parseDirectory 里
+// 以文件系统目录为键的 PackageInfo。
+func packageDirOf(pkg *packages.Package) string {
+	if len(pkg.GoFiles) > 0 {
+		return filepath.Dir(pkg.GoFiles[0])
+	}
+	if len(pkg.CompiledGoFiles) > 0 {
+		return filepath.Dir(pkg.CompiledGoFiles[0])
+	}
+	return pkg.PkgPath
+}
+
+// packageKeyOf 在 result.Packages 里找到 pkg 对应的键，兼容 parseDirectory
+// （以文件系统目录为键）和 parseModule（以模块导入路径为键）两种调用方式；
+// 两种都找不到时返回空字符串。
+func packageKeyOf(result *ParseResult, pkg *packages.Package) string {
+	if key := packageDirOf(pkg); isPackageKey(result, key) {
+		return key
+	}
+	if isPackageKey(result, pkg.PkgPath) {
+		return pkg.PkgPath
+	}
+	return ""
+}
+
+func isPackageKey(result *ParseResult, key string) bool {
+	_, ok := result.Packages[key]
+	return ok
+}
+
+// appendUnique 把 name 加入 slice，已存在则原样返回，避免重复项
+func appendUnique(slice []string, name string) []string {
+	for _, s := range slice {
+		if s == name {
+			return slice
+		}
+	}
+	return append(slice, name)
+}
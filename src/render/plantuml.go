@@ -0,0 +1,105 @@
+// Package render 把 parser.ParseResult 渲染成架构可视化文档，
+// 目前只有 PlantUML 一种实现；后续的渲染器（例如 Mermaid）可以挂在这个包下。
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Green-rainBit/ijump/src/parser"
+)
+
+// PlantUML 把 result 渲染成一段 PlantUML 类图（@startuml ... @enduml）。
+// packageFilter 为 nil 时渲染全部包，否则只渲染导入路径匹配该正则的包。
+func PlantUML(result parser.ParseResult, packageFilter *regexp.Regexp) string {
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+
+	for _, path := range sortedPackagePaths(result, packageFilter) {
+		writePackage(&b, result.Packages[path])
+	}
+
+	b.WriteString("@enduml\n")
+	return b.String()
+}
+
+// sortedPackagePaths 返回经过过滤、按字典序排序的包路径，
+// 排序是为了让同一份 ParseResult 每次都生成相同的 PlantUML 文本。
+func sortedPackagePaths(result parser.ParseResult, packageFilter *regexp.Regexp) []string {
+	paths := make([]string, 0, len(result.Packages))
+	for path := range result.Packages {
+		if packageFilter != nil && !packageFilter.MatchString(path) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func writePackage(b *strings.Builder, pkg parser.PackageInfo) {
+	for _, iface := range pkg.Interfaces {
+		writeInterface(b, iface)
+	}
+	for _, s := range pkg.Structs {
+		writeStruct(b, s)
+	}
+	for _, s := range pkg.Structs {
+		writeRelations(b, s)
+	}
+}
+
+func writeInterface(b *strings.Builder, iface parser.InterfaceInfo) {
+	fmt.Fprintf(b, "interface %s {\n", iface.Name)
+	for _, m := range iface.Methods {
+		fmt.Fprintf(b, "  +%s()\n", m.Name)
+	}
+	b.WriteString("}\n")
+}
+
+func writeStruct(b *strings.Builder, s parser.StructInfo) {
+	fmt.Fprintf(b, "class %s {\n", s.Name)
+	for _, f := range s.Fields {
+		if f.Embedded {
+			fmt.Fprintf(b, "  -%s: %s <<embed>>\n", f.Name, f.Type)
+		} else {
+			fmt.Fprintf(b, "  -%s: %s\n", f.Name, f.Type)
+		}
+	}
+	b.WriteString("}\n")
+}
+
+// writeRelations 输出结构体到接口的实现箭头，以及到内嵌字段的组合箭头
+func writeRelations(b *strings.Builder, s parser.StructInfo) {
+	for _, ifaceName := range implementedInterfaces(s) {
+		fmt.Fprintf(b, "%s ..|> %s\n", s.Name, ifaceName)
+	}
+	for _, f := range s.Fields {
+		if f.Embedded {
+			fmt.Fprintf(b, "%s *-- %s\n", s.Name, baseTypeName(f.Type))
+		}
+	}
+}
+
+// implementedInterfaces 优先使用 Implements（go/types 算出的真实实现关系，
+// 或经方法签名比对确认过的注释声明），Implements 为空时才回退到未经验证的注释声明
+func implementedInterfaces(s parser.StructInfo) []string {
+	if len(s.Implements) > 0 {
+		return s.Implements
+	}
+	return s.ImplementsInterfaces
+}
+
+// baseTypeName 去掉包限定符和泛型实参，只保留组合箭头需要的基础类型名，
+// 例如 "pkg.Inner[int]" 变成 "Inner"
+func baseTypeName(typeName string) string {
+	if idx := strings.Index(typeName, "["); idx >= 0 {
+		typeName = typeName[:idx]
+	}
+	if idx := strings.LastIndex(typeName, "."); idx >= 0 {
+		typeName = typeName[idx+1:]
+	}
+	return strings.TrimPrefix(typeName, "*")
+}